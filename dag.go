@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// taskState tracks a single task's completion so that dependents can wait on
+// it: done is closed once the task finishes (whether it ran, failed, or was
+// skipped), at which point exitCode and skipped are safe to read.
+type taskState struct {
+	done     chan struct{}
+	exitCode int
+	skipped  bool
+}
+
+// splitAttrs splits a task's post-label text into its "key=value ... -- "
+// attribute prefix and the remaining command, e.g. for
+// "depends_on=fetch,gen -- go build ./..." it returns
+// {"depends_on": "fetch,gen"} and "go build ./...". ok is false if there is
+// no attribute prefix, in which case cmd should be used unchanged.
+func splitAttrs(cmd string) (attrs map[string]string, rest string, ok bool) {
+	idx := strings.Index(cmd, " -- ")
+	if idx < 0 {
+		return nil, cmd, false
+	}
+
+	fields := strings.Fields(cmd[:idx])
+	if len(fields) == 0 {
+		return nil, cmd, false
+	}
+	for _, f := range fields {
+		if !strings.Contains(f, "=") {
+			return nil, cmd, false
+		}
+	}
+
+	attrs = map[string]string{}
+	for _, f := range fields {
+		kv := strings.SplitN(f, "=", 2)
+		attrs[kv[0]] = kv[1]
+	}
+	return attrs, strings.TrimSpace(cmd[idx+len(" -- "):]), true
+}
+
+// validateDAG checks that every depends_on label refers to a known task and
+// that the dependency graph is acyclic, using Kahn's algorithm. It returns
+// an error describing unknown labels or the cycle, if any.
+func validateDAG(tasks []Task) error {
+	known := map[string]bool{}
+	for _, t := range tasks {
+		known[t.Label] = true
+	}
+
+	inDegree := map[string]int{}
+	dependents := map[string][]string{}
+	for _, t := range tasks {
+		inDegree[t.Label] += 0
+		for _, dep := range t.DependsOn {
+			if !known[dep] {
+				return fmt.Errorf("task %q depends on unknown task %q", t.Label, dep)
+			}
+			inDegree[t.Label]++
+			dependents[dep] = append(dependents[dep], t.Label)
+		}
+	}
+
+	var queue []string
+	for label, deg := range inDegree {
+		if deg == 0 {
+			queue = append(queue, label)
+		}
+	}
+
+	visited := 0
+	for len(queue) > 0 {
+		label := queue[0]
+		queue = queue[1:]
+		visited++
+		for _, next := range dependents[label] {
+			inDegree[next]--
+			if inDegree[next] == 0 {
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	if visited < len(tasks) {
+		var remaining []string
+		for label, deg := range inDegree {
+			if deg > 0 {
+				remaining = append(remaining, label)
+			}
+		}
+		return fmt.Errorf("dependency cycle detected among tasks: %s", strings.Join(remaining, ", "))
+	}
+
+	return nil
+}
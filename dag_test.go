@@ -0,0 +1,148 @@
+package main
+
+import "testing"
+
+func TestSplitAttrs(t *testing.T) {
+	cases := []struct {
+		name     string
+		cmd      string
+		wantOK   bool
+		wantAttr map[string]string
+		wantRest string
+	}{
+		{
+			name:     "single attr",
+			cmd:      "depends_on=fetch,gen -- go build ./...",
+			wantOK:   true,
+			wantAttr: map[string]string{"depends_on": "fetch,gen"},
+			wantRest: "go build ./...",
+		},
+		{
+			name:   "multiple attrs",
+			cmd:    "depends_on=fetch retries=3 -- go test ./...",
+			wantOK: true,
+			wantAttr: map[string]string{
+				"depends_on": "fetch",
+				"retries":    "3",
+			},
+			wantRest: "go test ./...",
+		},
+		{
+			name:     "no attr separator",
+			cmd:      "go build ./...",
+			wantOK:   false,
+			wantRest: "go build ./...",
+		},
+		{
+			name:     "separator present but field has no equals",
+			cmd:      "depends_on fetch -- go build ./...",
+			wantOK:   false,
+			wantRest: "depends_on fetch -- go build ./...",
+		},
+		{
+			name:     "separator at start with nothing before it",
+			cmd:      " -- go build ./...",
+			wantOK:   false,
+			wantRest: " -- go build ./...",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			attrs, rest, ok := splitAttrs(tc.cmd)
+			if ok != tc.wantOK {
+				t.Fatalf("splitAttrs(%q) ok = %v, want %v", tc.cmd, ok, tc.wantOK)
+			}
+			if rest != tc.wantRest {
+				t.Errorf("splitAttrs(%q) rest = %q, want %q", tc.cmd, rest, tc.wantRest)
+			}
+			if tc.wantOK {
+				if len(attrs) != len(tc.wantAttr) {
+					t.Fatalf("splitAttrs(%q) attrs = %v, want %v", tc.cmd, attrs, tc.wantAttr)
+				}
+				for k, v := range tc.wantAttr {
+					if attrs[k] != v {
+						t.Errorf("splitAttrs(%q) attrs[%q] = %q, want %q", tc.cmd, k, attrs[k], v)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestValidateDAG(t *testing.T) {
+	cases := []struct {
+		name    string
+		tasks   []Task
+		wantErr bool
+	}{
+		{
+			name: "no dependencies",
+			tasks: []Task{
+				{Label: "a"},
+				{Label: "b"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "linear chain",
+			tasks: []Task{
+				{Label: "a"},
+				{Label: "b", DependsOn: []string{"a"}},
+				{Label: "c", DependsOn: []string{"b"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "diamond",
+			tasks: []Task{
+				{Label: "a"},
+				{Label: "b", DependsOn: []string{"a"}},
+				{Label: "c", DependsOn: []string{"a"}},
+				{Label: "d", DependsOn: []string{"b", "c"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown dependency",
+			tasks: []Task{
+				{Label: "a", DependsOn: []string{"missing"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "self cycle",
+			tasks: []Task{
+				{Label: "a", DependsOn: []string{"a"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "two-task cycle",
+			tasks: []Task{
+				{Label: "a", DependsOn: []string{"b"}},
+				{Label: "b", DependsOn: []string{"a"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "cycle among a larger graph",
+			tasks: []Task{
+				{Label: "a"},
+				{Label: "b", DependsOn: []string{"a"}},
+				{Label: "c", DependsOn: []string{"d"}},
+				{Label: "d", DependsOn: []string{"c"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateDAG(tc.tasks)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("validateDAG(%+v) error = %v, wantErr %v", tc.tasks, err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// runAttempt starts and waits for a single execution of task's command,
+// applying its cwd/env/timeout, and returns the outcome along with the
+// captured output buffers. setupErr is non-nil only if the command could not
+// be started at all (e.g. a pipe or fork failure), in which case the other
+// return values are meaningless.
+func runAttempt(ctx context.Context, task Task, words []string) (exitCode int, runErr error, signaled bool, startTime, endTime time.Time, stdoutBuf, stderrBuf *ringBuffer, setupErr error) {
+	execCtx := ctx
+	cancel := func() {}
+	if task.Timeout != nil {
+		execCtx, cancel = context.WithTimeout(ctx, *task.Timeout)
+	}
+	defer cancel()
+
+	cmd := exec.CommandContext(execCtx, words[0], words[1:]...)
+	if task.Cwd != "" {
+		cmd.Dir = task.Cwd
+	}
+	if len(task.Env) > 0 {
+		env := os.Environ()
+		for k, v := range task.Env {
+			env = append(env, k+"="+v)
+		}
+		cmd.Env = env
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return -1, nil, false, time.Time{}, time.Time{}, nil, nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return -1, nil, false, time.Time{}, time.Time{}, nil, nil, err
+	}
+
+	startTime = time.Now()
+	if err := cmd.Start(); err != nil {
+		return -1, nil, false, time.Time{}, time.Time{}, nil, nil, err
+	}
+
+	stdoutBuf = newRingBuffer(args.ReportMaxBytes)
+	stderrBuf = newRingBuffer(args.ReportMaxBytes)
+
+	var wgOut sync.WaitGroup
+	wgOut.Add(2)
+	go copyOutput(task, stdout, os.Stdout, stdoutBuf, &wgOut)
+	go copyOutput(task, stderr, os.Stderr, stderrBuf, &wgOut)
+	wgOut.Wait()
+
+	runErr = cmd.Wait()
+	endTime = time.Now()
+	if runErr != nil {
+		if exitErr, ok := runErr.(*exec.ExitError); ok {
+			exitCode = exitErr.ExitCode()
+			if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+				signaled = ws.Signaled()
+			}
+		} else {
+			exitCode = -1
+		}
+	}
+
+	return exitCode, runErr, signaled, startTime, endTime, stdoutBuf, stderrBuf, nil
+}
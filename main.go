@@ -2,18 +2,20 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"hash/fnv"
 	"io"
 	"os"
-	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/google/shlex"
 	flag "github.com/spf13/pflag"
@@ -37,27 +39,46 @@ var Reset = "\033[0m"
 
 var maxWidth int
 var args Args
+var hostname string
 
 type Task struct {
-	Label   string
-	Color   int
-	Command string
+	Label     string
+	Color     int
+	Command   string
+	DependsOn []string
+	Retries   *int
+	Backoff   *time.Duration
+	Cwd       string
+	Env       map[string]string
+	Timeout   *time.Duration
 }
 
 type TaskResult struct {
 	Task     Task
 	ExitCode int
 	Err      error
+	Skipped  bool
+	Attempts int
 }
 
 type Args struct {
-	Tasks       []Task
-	File        string
-	Quiet       bool
-	AbortOnFail bool
-	Raw         bool
-	NoColor     bool
-	Max         int
+	Tasks          []Task
+	File           string
+	Quiet          bool
+	AbortOnFail    bool
+	Raw            bool
+	NoColor        bool
+	Max            int
+	Reporters      []string
+	Notifiers      []string
+	Tag            string
+	ReportMaxBytes int
+	ContinueOnFail bool
+	Output         string
+	Retries        int
+	RetryBackoff   time.Duration
+	RetryOnExit    []int
+	Progress       bool
 }
 
 func getColor(label string) int {
@@ -66,22 +87,32 @@ func getColor(label string) int {
 	return int(h.Sum32()) % len(Colors)
 }
 
-func readLines(path string) ([]string, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
+func splitTaskLines(data []byte) []string {
 	var lines []string
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 		if line != "" && !strings.HasPrefix(line, "#") {
 			lines = append(lines, line)
 		}
 	}
-	return lines, scanner.Err()
+	return lines
+}
+
+func inferLabel(cmd string) string {
+	fields := strings.Fields(cmd)
+	base := filepath.Base(fields[0])
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext)
+}
+
+func dedupeLabel(labelCount map[string]int, label string) string {
+	count := labelCount[label]
+	if count > 0 {
+		label = fmt.Sprintf("%s (%d)", label, count+1)
+	}
+	labelCount[label] = count + 1
+	return label
 }
 
 func parseTasks(lines []string) []Task {
@@ -105,24 +136,40 @@ func parseTasks(lines []string) []Task {
 			}
 		}
 
-		if label == "" {
-			fields := strings.Fields(cmd)
-			base := filepath.Base(fields[0])
-			ext := filepath.Ext(base)
-			label = strings.TrimSuffix(base, ext)
+		var dependsOn []string
+		var retries *int
+		var backoff *time.Duration
+		if label != "" {
+			if attrs, rest, ok := splitAttrs(cmd); ok {
+				if dep, ok := attrs["depends_on"]; ok && dep != "" {
+					dependsOn = strings.Split(dep, ",")
+				}
+				if r, ok := attrs["retries"]; ok {
+					if n, err := strconv.Atoi(r); err == nil {
+						retries = &n
+					}
+				}
+				if b, ok := attrs["backoff"]; ok {
+					if d, err := time.ParseDuration(b); err == nil {
+						backoff = &d
+					}
+				}
+				cmd = rest
+			}
 		}
 
-		origLabel := label
-		count := labelCount[origLabel]
-		if count > 0 {
-			label = fmt.Sprintf("%s (%d)", origLabel, count+1)
+		if label == "" {
+			label = inferLabel(cmd)
 		}
-		labelCount[origLabel] = count + 1
+		label = dedupeLabel(labelCount, label)
 
 		tasks = append(tasks, Task{
-			Label:   label,
-			Command: cmd,
-			Color:   getColor(label),
+			Label:     label,
+			Command:   cmd,
+			Color:     getColor(label),
+			DependsOn: dependsOn,
+			Retries:   retries,
+			Backoff:   backoff,
 		})
 	}
 
@@ -140,6 +187,20 @@ func calcMaxWidth(tasks []Task) int {
 }
 
 func writeOut(task Task, message string, w io.Writer) {
+	if args.Output == "json" {
+		stream := "stdout"
+		if w == os.Stderr {
+			stream = "stderr"
+		}
+		emitJSON(jsonEvent{
+			Ts:     time.Now().Format(time.RFC3339Nano),
+			Label:  task.Label,
+			Stream: stream,
+			Line:   message,
+		})
+		return
+	}
+
 	if args.Raw {
 		fmt.Fprintln(w, message)
 		return
@@ -168,6 +229,27 @@ func parseArgs() {
 		"Maximum concurrent tasks (0 = unlimited)")
 	flag.BoolVarP(&args.AbortOnFail, "abort-on-fail", "a", false,
 		"Stop all tasks if any fail")
+	flag.StringArrayVar(&args.Reporters, "reporter", nil,
+		"Command invoked once per task with a JSON report on stdin (repeatable)")
+	flag.StringArrayVar(&args.Notifiers, "notifier", nil,
+		"Command invoked with a JSON report on stdin only when a task exits non-zero (repeatable)")
+	flag.StringVar(&args.Tag, "tag", "",
+		"User string included as-is in every JSON report")
+	flag.IntVar(&args.ReportMaxBytes, "report-max-bytes", 64*1024,
+		"Maximum bytes of stdout/stderr captured per task for reports (0 = unlimited)")
+	flag.BoolVar(&args.ContinueOnFail, "continue-on-fail", false,
+		"Run downstream tasks even if a depends_on task fails, instead of skipping them")
+	flag.StringVar(&args.Output, "output", "",
+		"Output format: \"json\" for JSON-lines output instead of labeled text (mutually exclusive with --raw)")
+	flag.IntVar(&args.Retries, "retries", 0,
+		"Number of times to retry a failing task (overridable per-task with retries=N)")
+	flag.DurationVar(&args.RetryBackoff, "retry-backoff", time.Second,
+		"Base delay between retries, doubled after each attempt and capped (overridable per-task with backoff=<duration>)")
+	var retryOnExit string
+	flag.StringVar(&retryOnExit, "retry-on-exit", "",
+		"Comma-separated exit codes that trigger a retry (default: any non-zero exit code)")
+	flag.BoolVar(&args.Progress, "progress", false,
+		"Render an in-place live dashboard instead of interleaved output (requires a TTY stdout; ignored with --raw or --output=json)")
 
 	flag.Usage = func() {
 		fmt.Fprint(os.Stderr, `Usage:
@@ -191,102 +273,206 @@ Flags:
 
 	flag.Parse()
 
+	if retryOnExit != "" {
+		for _, code := range strings.Split(retryOnExit, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(code))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error: invalid --retry-on-exit code:", code)
+				os.Exit(1)
+			}
+			args.RetryOnExit = append(args.RetryOnExit, n)
+		}
+	}
+
+	if args.Output != "" && args.Output != "json" {
+		fmt.Fprintln(os.Stderr, "Error: --output must be \"json\" if set")
+		os.Exit(1)
+	}
+	if args.Output == "json" && args.Raw {
+		fmt.Fprintln(os.Stderr, "Error: --output=json and --raw are mutually exclusive")
+		os.Exit(1)
+	}
+
 	if args.Raw {
 		args.Quiet = true
 	}
 
-	var lines []string
 	if args.File != "" {
-		fileLines, err := readLines(args.File)
+		data, err := os.ReadFile(args.File)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, "Error reading file:", err)
 			os.Exit(1)
 		}
-		lines = fileLines
+
+		if looksLikeYAML(args.File, data) {
+			tasks, err := parseYAMLTasks(data)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error parsing YAML tasks:", err)
+				os.Exit(1)
+			}
+			args.Tasks = tasks
+		} else {
+			args.Tasks = parseTasks(splitTaskLines(data))
+		}
 	} else {
-		lines = flag.Args()
+		args.Tasks = parseTasks(flag.Args())
 	}
 
-	args.Tasks = parseTasks(lines)
-
 	if len(args.Tasks) == 0 {
 		fmt.Fprintln(os.Stderr, "No tasks provided.\n")
 		flag.Usage()
 		os.Exit(1)
 	}
+
+	if err := validateDAG(args.Tasks); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
 }
 
-func runTask(ctx context.Context, task Task, wg *sync.WaitGroup, sem chan struct{}, exitOnFail *int32, results chan<- TaskResult) {
+func runTask(ctx context.Context, task Task, wg *sync.WaitGroup, sem chan struct{}, exitOnFail *int32, states map[string]*taskState, results chan<- TaskResult) {
 	defer wg.Done()
 
+	state := states[task.Label]
+	finish := func(exitCode int, err error, skipped bool, lifecycle string, attempts int) {
+		state.exitCode = exitCode
+		state.skipped = skipped
+		close(state.done)
+		if args.Output == "json" {
+			ec := exitCode
+			jsonMetaEvent(task.Label, lifecycle, &ec)
+		}
+		if progressEnabled {
+			status := statusOK
+			line := ""
+			switch {
+			case skipped || lifecycle == "aborted":
+				status = statusSkipped
+				if err != nil {
+					line = err.Error()
+				}
+			case exitCode != 0:
+				status = statusFailed
+				if err != nil {
+					line = err.Error()
+				}
+			}
+			sendProgress(task.Label, status, line)
+		}
+		results <- TaskResult{task, exitCode, err, skipped, attempts}
+	}
+
+	for _, dep := range task.DependsOn {
+		depState := states[dep]
+		<-depState.done
+		if (depState.skipped || depState.exitCode != 0) && !args.ContinueOnFail {
+			finish(-1, fmt.Errorf("skipped: dependency %q did not succeed", dep), true, "aborted", 0)
+			return
+		}
+	}
+
 	sem <- struct{}{}
 	defer func() { <-sem }()
 
 	if atomic.LoadInt32(exitOnFail) == 1 {
-		results <- TaskResult{task, -1, fmt.Errorf("aborted")}
+		finish(-1, fmt.Errorf("aborted"), false, "aborted", 0)
 		return
 	}
 
 	words, err := shlex.Split(task.Command)
 	if err != nil {
-		results <- TaskResult{task, -1, err}
+		finish(-1, err, false, "finished", 0)
 		return
 	}
 	if len(words) == 0 {
-		results <- TaskResult{task, -1, fmt.Errorf("empty command")}
+		finish(-1, fmt.Errorf("empty command"), false, "finished", 0)
 		return
 	}
 
-	cmd := exec.CommandContext(ctx, words[0], words[1:]...)
-
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		results <- TaskResult{task, -1, err}
-		return
+	maxRetries := args.Retries
+	if task.Retries != nil {
+		maxRetries = *task.Retries
 	}
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		results <- TaskResult{task, -1, err}
-		return
+	backoff := args.RetryBackoff
+	if task.Backoff != nil {
+		backoff = *task.Backoff
 	}
 
-	if !args.Quiet {
-		writeOut(task, "Running: "+task.Command, os.Stdout)
-	}
+	var (
+		exitCode  int
+		runErr    error
+		signaled  bool
+		startTime time.Time
+		endTime   time.Time
+		stdoutBuf *ringBuffer
+		stderrBuf *ringBuffer
+	)
+
+	attempt := 0
+	for {
+		if attempt == 0 {
+			if args.Output == "json" {
+				jsonMetaEvent(task.Label, "started", nil)
+			} else if progressEnabled {
+				sendProgress(task.Label, statusRunning, "")
+			} else if !args.Quiet {
+				writeOut(task, "Running: "+task.Command, os.Stdout)
+			}
+		}
 
-	if err := cmd.Start(); err != nil {
-		results <- TaskResult{task, -1, err}
-		return
-	}
+		var setupErr error
+		exitCode, runErr, signaled, startTime, endTime, stdoutBuf, stderrBuf, setupErr = runAttempt(ctx, task, words)
+		if setupErr != nil {
+			finish(-1, setupErr, false, "finished", attempt)
+			return
+		}
 
-	var wgOut sync.WaitGroup
-	wgOut.Add(2)
-	go copyOutput(task, stdout, os.Stdout, &wgOut)
-	go copyOutput(task, stderr, os.Stderr, &wgOut)
-	wgOut.Wait()
+		if exitCode == 0 || attempt >= maxRetries || !shouldRetryExitCode(exitCode, args.RetryOnExit) ||
+			ctx.Err() != nil || atomic.LoadInt32(exitOnFail) == 1 {
+			break
+		}
 
-	err = cmd.Wait()
-	exitCode := 0
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			exitCode = exitErr.ExitCode()
+		wait := retryBackoff(backoff, attempt)
+		if args.Output == "json" {
+			jsonMetaEvent(task.Label, "retry", nil)
 		} else {
-			exitCode = -1
+			logLine(task, fmt.Sprintf("Retry %d/%d after: %s", attempt+1, maxRetries, runErr), os.Stdout)
 		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+		}
+		attempt++
 	}
 
 	if args.AbortOnFail && exitCode != 0 {
 		atomic.StoreInt32(exitOnFail, 1)
 	}
 
-	results <- TaskResult{task, exitCode, err}
+	dispatchReport(task, TaskReport{
+		Label:     task.Label,
+		Command:   task.Command,
+		ExitCode:  exitCode,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Hostname:  hostname,
+		Stdout:    stdoutBuf.String(),
+		Stderr:    stderrBuf.String(),
+		Signaled:  signaled,
+		Tag:       args.Tag,
+	})
+
+	finish(exitCode, runErr, false, "finished", attempt)
 }
 
-func copyOutput(task Task, r io.Reader, w io.Writer, wg *sync.WaitGroup) {
+func copyOutput(task Task, r io.Reader, w io.Writer, buf *ringBuffer, wg *sync.WaitGroup) {
 	defer wg.Done()
 	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
-		writeOut(task, scanner.Text(), w)
+		line := scanner.Text()
+		logLine(task, line, w)
+		buf.Write([]byte(line))
+		buf.Write([]byte("\n"))
 	}
 }
 
@@ -294,6 +480,12 @@ func main() {
 	parseArgs()
 	maxWidth = calcMaxWidth(args.Tasks)
 
+	if h, err := os.Hostname(); err == nil {
+		hostname = h
+	}
+
+	progressEnabled = args.Progress && !args.Raw && args.Output != "json" && isTerminal(os.Stdout)
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -301,7 +493,9 @@ func main() {
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
 	go func() {
 		sig := <-sigCh
-		fmt.Printf("\n\nReceived signal: %v. Finishing running tasks...\n", sig)
+		if !progressEnabled {
+			fmt.Printf("\n\nReceived signal: %v. Finishing running tasks...\n", sig)
+		}
 		cancel()
 	}()
 
@@ -310,13 +504,28 @@ func main() {
 		sem = make(chan struct{}, len(args.Tasks))
 	}
 
+	var progressWG sync.WaitGroup
+	if progressEnabled {
+		progressCh = make(chan progressEvent, 256)
+		progressWG.Add(1)
+		go func() {
+			defer progressWG.Done()
+			runProgressRenderer(args.Tasks)
+		}()
+	}
+
 	var wg sync.WaitGroup
 	results := make(chan TaskResult, len(args.Tasks))
 	var exitOnFail int32 = 0
 
+	states := make(map[string]*taskState, len(args.Tasks))
+	for _, task := range args.Tasks {
+		states[task.Label] = &taskState{done: make(chan struct{})}
+	}
+
 	for _, task := range args.Tasks {
 		wg.Add(1)
-		go runTask(ctx, task, &wg, sem, &exitOnFail, results)
+		go runTask(ctx, task, &wg, sem, &exitOnFail, states, results)
 	}
 
 	go func() {
@@ -326,23 +535,59 @@ func main() {
 
 	successCount := 0
 	failCount := 0
+	skipCount := 0
 	failLabels := []string{}
+	skipLabels := []string{}
+	retriedLabels := []string{}
 
 	for res := range results {
-		if res.ExitCode == 0 {
+		switch {
+		case res.Skipped:
+			skipCount++
+			skipLabels = append(skipLabels, res.Task.Label)
+		case res.ExitCode == 0:
 			successCount++
-		} else {
+		default:
 			failCount++
 			failLabels = append(failLabels, res.Task.Label)
-			writeOut(res.Task, fmt.Sprintf("Error: %s", res.Err), os.Stderr)
+			if args.Output != "json" && !progressEnabled {
+				writeOut(res.Task, fmt.Sprintf("Error: %s", res.Err), os.Stderr)
+			}
+		}
+		if res.Attempts > 0 {
+			retriedLabels = append(retriedLabels, fmt.Sprintf("%s (%d)", res.Task.Label, res.Attempts))
 		}
 	}
 
-	if !args.Quiet {
-		fmt.Printf("\nTasks finished: %d / %d succeeded, %d failed\n", successCount, len(args.Tasks), failCount)
+	if progressEnabled {
+		close(progressCh)
+		progressWG.Wait()
+	}
+
+	if args.Output == "json" {
+		if !args.Quiet {
+			emitJSON(jsonSummary{
+				Ts:           time.Now().Format(time.RFC3339Nano),
+				Total:        len(args.Tasks),
+				Succeeded:    successCount,
+				Failed:       failCount,
+				Skipped:      skipCount,
+				FailedTasks:  failLabels,
+				SkippedTasks: skipLabels,
+			})
+		}
+	} else if !args.Quiet {
+		fmt.Printf("\nTasks finished: %d / %d succeeded, %d failed, %d skipped\n", successCount, len(args.Tasks), failCount, skipCount)
 		if failCount > 0 {
 			fmt.Printf("Failed tasks: %s\n", strings.Join(failLabels, ", "))
-		} else {
+		}
+		if skipCount > 0 {
+			fmt.Printf("Skipped tasks: %s\n", strings.Join(skipLabels, ", "))
+		}
+		if len(retriedLabels) > 0 {
+			fmt.Printf("Retried tasks: %s\n", strings.Join(retriedLabels, ", "))
+		}
+		if failCount == 0 && skipCount == 0 {
 			fmt.Println("All tasks completed successfully!")
 		}
 	}
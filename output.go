@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// jsonEvent is one line of --output=json output: either a chunk of task
+// output (stream "stdout"/"stderr") or a lifecycle marker (stream "meta",
+// line one of "started"/"finished"/"aborted").
+type jsonEvent struct {
+	Ts       string `json:"ts"`
+	Label    string `json:"label"`
+	Stream   string `json:"stream"`
+	ExitCode *int   `json:"exit_code,omitempty"`
+	Line     string `json:"line,omitempty"`
+}
+
+// jsonSummary is emitted once, after all tasks finish, as the final line of
+// --output=json output.
+type jsonSummary struct {
+	Ts           string   `json:"ts"`
+	Total        int      `json:"total"`
+	Succeeded    int      `json:"succeeded"`
+	Failed       int      `json:"failed"`
+	Skipped      int      `json:"skipped"`
+	FailedTasks  []string `json:"failed_tasks,omitempty"`
+	SkippedTasks []string `json:"skipped_tasks,omitempty"`
+}
+
+var jsonMu sync.Mutex
+
+// emitJSON marshals v and writes it as a single line to stdout, guarded by a
+// mutex so lines from concurrent tasks never interleave mid-record.
+func emitJSON(v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to marshal JSON output:", err)
+		return
+	}
+
+	jsonMu.Lock()
+	defer jsonMu.Unlock()
+	fmt.Fprintln(os.Stdout, string(data))
+}
+
+// jsonMetaEvent emits a lifecycle event for a task.
+func jsonMetaEvent(label, line string, exitCode *int) {
+	emitJSON(jsonEvent{
+		Ts:       time.Now().Format(time.RFC3339Nano),
+		Label:    label,
+		Stream:   "meta",
+		Line:     line,
+		ExitCode: exitCode,
+	})
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+type progressStatus string
+
+const (
+	statusPending progressStatus = "pending"
+	statusRunning progressStatus = "running"
+	statusOK      progressStatus = "ok"
+	statusFailed  progressStatus = "failed"
+	statusSkipped progressStatus = "skipped"
+)
+
+// progressEvent is sent from task goroutines to the single renderer
+// goroutine so the dashboard can be redrawn without any locking on the
+// terminal itself.
+type progressEvent struct {
+	Label    string
+	Status   progressStatus
+	LastLine string
+}
+
+var progressEnabled bool
+var progressCh chan progressEvent
+
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// sendProgress forwards an update to the progress renderer; it is a no-op
+// when the dashboard isn't active, so call sites don't need to guard it.
+func sendProgress(label string, status progressStatus, line string) {
+	if !progressEnabled {
+		return
+	}
+	progressCh <- progressEvent{Label: label, Status: status, LastLine: line}
+}
+
+// logLine is the shared entry point for a line of task output or a meta
+// message: it feeds the dashboard when --progress is active, otherwise it
+// falls back to the normal labeled/raw/JSON writeOut path.
+func logLine(task Task, message string, w io.Writer) {
+	if progressEnabled {
+		sendProgress(task.Label, statusRunning, message)
+		return
+	}
+	writeOut(task, message, w)
+}
+
+type progressRow struct {
+	Status    progressStatus
+	LastLine  string
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+func isTerminalStatus(s progressStatus) bool {
+	return s == statusOK || s == statusFailed || s == statusSkipped
+}
+
+// runProgressRenderer owns the terminal while the dashboard is active: it is
+// the only goroutine that writes to stdout, redrawing in place on every
+// event and on a ticker (so elapsed time keeps moving between events).
+//
+// The number of rows drawn per frame is clamped to the terminal height so
+// that the dashboard never causes the terminal to scroll; once it scrolls,
+// the saved cursor position it redraws to would no longer point at the top
+// of the dashboard. Tasks beyond the visible rows are summarized in a
+// trailing "+N more" line instead.
+func runProgressRenderer(tasks []Task) {
+	order := make([]string, 0, len(tasks))
+	rows := make(map[string]*progressRow, len(tasks))
+	for _, t := range tasks {
+		order = append(order, t.Label)
+		rows[t.Label] = &progressRow{Status: statusPending}
+	}
+
+	visibleRows := len(order)
+	if _, height, err := term.GetSize(int(os.Stdout.Fd())); err == nil && height > 2 {
+		if max := height - 2; max < visibleRows {
+			visibleRows = max
+		}
+	}
+
+	fmt.Print("\033[s") // save cursor position; every redraw restores here
+
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	render := func() {
+		fmt.Print("\033[u")
+
+		completed := 0
+		for _, label := range order {
+			if rows[label].Status != statusPending && isTerminalStatus(rows[label].Status) {
+				completed++
+			}
+		}
+
+		for _, label := range order[:visibleRows] {
+			row := rows[label]
+			elapsed := ""
+			end := row.EndTime
+			if end.IsZero() {
+				end = time.Now()
+			}
+			if !row.StartTime.IsZero() {
+				elapsed = end.Sub(row.StartTime).Round(time.Second).String()
+			}
+			padding := maxWidth - len(label)
+			if padding < 0 {
+				padding = 0
+			}
+			fmt.Printf("\033[K%s%s | %-7s | %8s | %s\n",
+				label, strings.Repeat(" ", padding), row.Status, elapsed, row.LastLine)
+		}
+		if hidden := len(order) - visibleRows; hidden > 0 {
+			fmt.Printf("\033[K... and %d more task(s) not shown\n", hidden)
+		}
+		fmt.Printf("\033[K%d/%d complete\n", completed, len(order))
+	}
+
+	render()
+	for {
+		select {
+		case ev, ok := <-progressCh:
+			if !ok {
+				render()
+				return
+			}
+			row := rows[ev.Label]
+			if row.Status == statusPending && ev.Status == statusRunning {
+				row.StartTime = time.Now()
+			}
+			if isTerminalStatus(ev.Status) {
+				row.EndTime = time.Now()
+			}
+			row.Status = ev.Status
+			if ev.LastLine != "" {
+				row.LastLine = ev.LastLine
+			}
+			render()
+		case <-ticker.C:
+			render()
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/google/shlex"
+)
+
+// TaskReport is the structured record emitted to reporters/notifiers once a
+// task has finished running.
+type TaskReport struct {
+	Label     string    `json:"label"`
+	Command   string    `json:"command"`
+	ExitCode  int       `json:"exit_code"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Hostname  string    `json:"hostname"`
+	Stdout    string    `json:"stdout"`
+	Stderr    string    `json:"stderr"`
+	Signaled  bool      `json:"signaled"`
+	Tag       string    `json:"tag,omitempty"`
+}
+
+// ringBuffer is a concurrency-safe []byte buffer that keeps only the last
+// max bytes written to it, discarding the oldest data once full.
+type ringBuffer struct {
+	mu  sync.Mutex
+	max int
+	buf []byte
+}
+
+func newRingBuffer(max int) *ringBuffer {
+	return &ringBuffer{max: max}
+}
+
+func (r *ringBuffer) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.buf = append(r.buf, p...)
+	if r.max > 0 && len(r.buf) > r.max {
+		r.buf = r.buf[len(r.buf)-r.max:]
+	}
+	return len(p), nil
+}
+
+func (r *ringBuffer) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return string(r.buf)
+}
+
+// runHook invokes an external command with payload fed to it on stdin, used
+// for both --reporter and --notifier. Its stdout/stderr are captured rather
+// than wired to the process's own streams: a hook is just another source of
+// task-related output, so it goes through logLine like any other task line
+// instead of writing to the terminal directly, which would otherwise
+// interleave raw text into the --output=json stream or corrupt the
+// --progress dashboard's redraws.
+func runHook(task Task, hookCmd string, payload []byte) {
+	words, err := shlex.Split(hookCmd)
+	if err != nil || len(words) == 0 {
+		fmt.Fprintln(os.Stderr, "Invalid hook command:", hookCmd)
+		return
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(words[0], words[1:]...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	emitHookOutput(task, stdout.Bytes(), os.Stdout)
+	emitHookOutput(task, stderr.Bytes(), os.Stderr)
+
+	if runErr != nil {
+		fmt.Fprintln(os.Stderr, "Hook failed:", hookCmd, "-", runErr)
+	}
+}
+
+// emitHookOutput feeds a hook's captured output through logLine line by
+// line, the same path task output takes, so it is labeled in plain mode,
+// folded into the --output=json stream, and absorbed by the --progress
+// dashboard instead of bypassing it.
+func emitHookOutput(task Task, data []byte, w io.Writer) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		logLine(task, scanner.Text(), w)
+	}
+}
+
+// dispatchReport marshals the report to JSON and fans it out to every
+// configured reporter, and to every notifier if the task failed.
+func dispatchReport(task Task, report TaskReport) {
+	if len(args.Reporters) == 0 && len(args.Notifiers) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(report)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to marshal report:", err)
+		return
+	}
+
+	for _, reporter := range args.Reporters {
+		runHook(task, reporter, payload)
+	}
+
+	if report.ExitCode != 0 {
+		for _, notifier := range args.Notifiers {
+			runHook(task, notifier, payload)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import "time"
+
+// maxRetryBackoff caps the exponential backoff delay between retries so a
+// large retry count can't leave a task waiting for an absurd amount of time.
+const maxRetryBackoff = 5 * time.Minute
+
+// shouldRetryExitCode reports whether exitCode qualifies for a retry. With
+// no codes configured, any non-zero exit code qualifies.
+func shouldRetryExitCode(exitCode int, codes []int) bool {
+	if len(codes) == 0 {
+		return exitCode != 0
+	}
+	for _, c := range codes {
+		if c == exitCode {
+			return true
+		}
+	}
+	return false
+}
+
+// retryBackoff returns the delay before the next attempt: base * 2^attempt,
+// capped at maxRetryBackoff.
+func retryBackoff(base time.Duration, attempt int) time.Duration {
+	wait := base << attempt
+	if wait > maxRetryBackoff || wait <= 0 {
+		return maxRetryBackoff
+	}
+	return wait
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShouldRetryExitCode(t *testing.T) {
+	cases := []struct {
+		name     string
+		exitCode int
+		codes    []int
+		want     bool
+	}{
+		{"no codes configured, success", 0, nil, false},
+		{"no codes configured, failure", 1, nil, true},
+		{"exit code in list", 2, []int{1, 2, 3}, true},
+		{"exit code not in list", 4, []int{1, 2, 3}, false},
+		{"exit code in list but zero", 0, []int{0}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := shouldRetryExitCode(tc.exitCode, tc.codes)
+			if got != tc.want {
+				t.Errorf("shouldRetryExitCode(%d, %v) = %v, want %v", tc.exitCode, tc.codes, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	cases := []struct {
+		name    string
+		base    time.Duration
+		attempt int
+		want    time.Duration
+	}{
+		{"attempt 0 returns base", time.Second, 0, time.Second},
+		{"attempt 1 doubles", time.Second, 1, 2 * time.Second},
+		{"attempt 3 is base * 8", time.Second, 3, 8 * time.Second},
+		{"capped at maxRetryBackoff", time.Minute, 10, maxRetryBackoff},
+		{"overflow falls back to cap", time.Hour, 62, maxRetryBackoff},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := retryBackoff(tc.base, tc.attempt)
+			if got != tc.want {
+				t.Errorf("retryBackoff(%v, %d) = %v, want %v", tc.base, tc.attempt, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlFile is the schema for a YAML task file, e.g.:
+//
+//	tasks:
+//	  - label: build
+//	    command: go build ./...
+//	    cwd: ./app
+//	    env:
+//	      CGO_ENABLED: "0"
+//	    timeout: 30s
+//	    retries: 3
+//	    depends_on: [fetch, gen]
+type yamlFile struct {
+	Tasks []yamlTask `yaml:"tasks"`
+}
+
+type yamlTask struct {
+	Label     string            `yaml:"label"`
+	Command   string            `yaml:"command"`
+	Cwd       string            `yaml:"cwd"`
+	Env       map[string]string `yaml:"env"`
+	Timeout   string            `yaml:"timeout"`
+	Retries   *int              `yaml:"retries"`
+	Backoff   string            `yaml:"backoff"`
+	DependsOn []string          `yaml:"depends_on"`
+}
+
+// looksLikeYAML decides whether a task file should be parsed as YAML rather
+// than the one-line-per-task text format, based on its extension or a
+// leading "---" document marker.
+func looksLikeYAML(path string, data []byte) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".yaml" || ext == ".yml" {
+		return true
+	}
+	return strings.HasPrefix(strings.TrimSpace(string(data)), "---")
+}
+
+func parseYAMLTasks(data []byte) ([]Task, error) {
+	var file yamlFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	labelCount := map[string]int{}
+	tasks := make([]Task, 0, len(file.Tasks))
+
+	for i, yt := range file.Tasks {
+		if strings.TrimSpace(yt.Command) == "" {
+			return nil, fmt.Errorf("tasks[%d]: command is required", i)
+		}
+
+		label := yt.Label
+		if label == "" {
+			label = inferLabel(yt.Command)
+		}
+		label = dedupeLabel(labelCount, label)
+
+		var timeout *time.Duration
+		if yt.Timeout != "" {
+			d, err := time.ParseDuration(yt.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("tasks[%d] (%s): invalid timeout %q: %w", i, label, yt.Timeout, err)
+			}
+			timeout = &d
+		}
+
+		var backoff *time.Duration
+		if yt.Backoff != "" {
+			d, err := time.ParseDuration(yt.Backoff)
+			if err != nil {
+				return nil, fmt.Errorf("tasks[%d] (%s): invalid backoff %q: %w", i, label, yt.Backoff, err)
+			}
+			backoff = &d
+		}
+
+		tasks = append(tasks, Task{
+			Label:     label,
+			Command:   yt.Command,
+			Color:     getColor(label),
+			DependsOn: yt.DependsOn,
+			Retries:   yt.Retries,
+			Backoff:   backoff,
+			Cwd:       yt.Cwd,
+			Env:       yt.Env,
+			Timeout:   timeout,
+		})
+	}
+
+	return tasks, nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseYAMLTasksFields(t *testing.T) {
+	data := []byte(`
+tasks:
+  - label: build
+    command: go build ./...
+    cwd: ./app
+    env:
+      CGO_ENABLED: "0"
+    timeout: 30s
+    backoff: 2s
+    depends_on: [fetch, gen]
+`)
+
+	tasks, err := parseYAMLTasks(data)
+	if err != nil {
+		t.Fatalf("parseYAMLTasks() error = %v", err)
+	}
+	if len(tasks) != 1 {
+		t.Fatalf("parseYAMLTasks() = %d tasks, want 1", len(tasks))
+	}
+
+	got := tasks[0]
+	if got.Label != "build" {
+		t.Errorf("Label = %q, want %q", got.Label, "build")
+	}
+	if got.Command != "go build ./..." {
+		t.Errorf("Command = %q, want %q", got.Command, "go build ./...")
+	}
+	if got.Cwd != "./app" {
+		t.Errorf("Cwd = %q, want %q", got.Cwd, "./app")
+	}
+	if got.Env["CGO_ENABLED"] != "0" {
+		t.Errorf("Env[CGO_ENABLED] = %q, want %q", got.Env["CGO_ENABLED"], "0")
+	}
+	if got.Timeout == nil || *got.Timeout != 30*time.Second {
+		t.Errorf("Timeout = %v, want %v", got.Timeout, 30*time.Second)
+	}
+	if got.Backoff == nil || *got.Backoff != 2*time.Second {
+		t.Errorf("Backoff = %v, want %v", got.Backoff, 2*time.Second)
+	}
+	if len(got.DependsOn) != 2 || got.DependsOn[0] != "fetch" || got.DependsOn[1] != "gen" {
+		t.Errorf("DependsOn = %v, want [fetch gen]", got.DependsOn)
+	}
+}
+
+func TestParseYAMLTasksRetries(t *testing.T) {
+	data := []byte(`
+tasks:
+  - label: flaky
+    command: go test ./...
+    retries: 3
+`)
+
+	tasks, err := parseYAMLTasks(data)
+	if err != nil {
+		t.Fatalf("parseYAMLTasks() error = %v", err)
+	}
+	if tasks[0].Retries == nil || *tasks[0].Retries != 3 {
+		t.Errorf("Retries = %v, want 3", tasks[0].Retries)
+	}
+}
+
+func TestParseYAMLTasksLabelDefaultAndDedupe(t *testing.T) {
+	data := []byte(`
+tasks:
+  - command: echo hi
+  - command: echo hi
+`)
+
+	tasks, err := parseYAMLTasks(data)
+	if err != nil {
+		t.Fatalf("parseYAMLTasks() error = %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("parseYAMLTasks() = %d tasks, want 2", len(tasks))
+	}
+	if tasks[0].Label == tasks[1].Label {
+		t.Errorf("expected deduped labels, got %q and %q", tasks[0].Label, tasks[1].Label)
+	}
+}
+
+func TestParseYAMLTasksErrors(t *testing.T) {
+	cases := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{
+			name: "missing command",
+			data: `
+tasks:
+  - label: build
+`,
+			wantErr: "command is required",
+		},
+		{
+			name: "blank command",
+			data: `
+tasks:
+  - label: build
+    command: "   "
+`,
+			wantErr: "command is required",
+		},
+		{
+			name: "invalid timeout",
+			data: `
+tasks:
+  - label: build
+    command: go build ./...
+    timeout: not-a-duration
+`,
+			wantErr: "invalid timeout",
+		},
+		{
+			name: "invalid backoff",
+			data: `
+tasks:
+  - label: build
+    command: go build ./...
+    backoff: not-a-duration
+`,
+			wantErr: "invalid backoff",
+		},
+		{
+			name:    "malformed yaml",
+			data:    "tasks: [",
+			wantErr: "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseYAMLTasks([]byte(tc.data))
+			if err == nil {
+				t.Fatalf("parseYAMLTasks() error = nil, want error")
+			}
+			if tc.wantErr != "" && !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("parseYAMLTasks() error = %q, want to contain %q", err.Error(), tc.wantErr)
+			}
+		})
+	}
+}